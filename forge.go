@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// RepoInfo is the forge-agnostic view of a repository that RemoteResolver
+// needs in order to decide which repos a RemoteSpec applies to.
+type RepoInfo struct {
+	Name     string
+	Archived bool
+}
+
+// ForgeListReposOptions controls a single page of Forge.ListRepos.
+type ForgeListReposOptions struct {
+	// IsUser selects the user-owned-repos endpoint instead of the org one,
+	// mirroring RemoteSpec.User vs RemoteSpec.Org.
+	IsUser  bool
+	Page    int
+	PerPage int
+}
+
+// ForgeListReposResult is one page of repos plus the next page to fetch, or
+// zero when there are no more pages.
+type ForgeListReposResult struct {
+	Repos    []RepoInfo
+	NextPage int
+}
+
+// ForgeFileContent is the forge-agnostic view of a file's current remote
+// content, used to decide whether an update is needed and, if so, what SHA
+// to send back for a compare-and-swap update.
+type ForgeFileContent struct {
+	Content string
+	SHA     string
+}
+
+// RepoMetadata is the forge-agnostic repo metadata available to FileSpec
+// templates: the default branch and the repo's topics.
+type RepoMetadata struct {
+	DefaultBranch string
+	Topics        []string
+}
+
+// PullRequestOptions describes a pull/merge request to open for the
+// "pull_request" FileSpec mode.
+type PullRequestOptions struct {
+	Title     string
+	Body      string
+	Head      string
+	Base      string
+	Labels    []string
+	Reviewers []string
+}
+
+// Forge abstracts the handful of Git-forge operations FileMaintainer needs,
+// so that Processor and RemoteResolver can maintain files across GitHub,
+// Gitea, and GitLab without knowing which one they're talking to.
+type Forge interface {
+	ListRepos(ctx context.Context, owner string, opts ForgeListReposOptions) (ForgeListReposResult, error)
+	// GetContents returns the file's current content on ref (the default
+	// branch when ref is empty) and the HTTP status code of the underlying
+	// request (200 found, 404 missing) so callers can keep their existing
+	// status-code based branching.
+	GetContents(ctx context.Context, owner string, repo string, path string, ref string) (*ForgeFileContent, int, error)
+	// CreateOrUpdateFile creates path if sha is empty, otherwise updates it
+	// compare-and-swap style, committing to branch (the default branch when
+	// branch is empty). It returns the HTTP status code so callers can
+	// detect a conflict (409) and fall back to the git worktree path.
+	CreateOrUpdateFile(ctx context.Context, owner string, repo string, path string, message string, content []byte, sha string, branch string) (int, error)
+	// DeleteFile removes path at sha, committing to branch (the default
+	// branch when branch is empty). It returns the HTTP status code so
+	// callers can detect a conflict (409) and fall back to the git worktree
+	// path, the same way CreateOrUpdateFile does.
+	DeleteFile(ctx context.Context, owner string, repo string, path string, message string, sha string, branch string) (int, error)
+	CloneURL(owner string, repo string) string
+
+	DefaultBranch(ctx context.Context, owner string, repo string) (string, error)
+	// RepoMetadata fetches the repo details FileSpec.Template rendering
+	// needs; callers should cache the result per repo (see
+	// ResolvedRemote.Metadata) rather than calling this per file.
+	RepoMetadata(ctx context.Context, owner string, repo string) (RepoMetadata, error)
+	// EnsureBranch makes sure branch exists, branching it off base if not.
+	EnsureBranch(ctx context.Context, owner string, repo string, branch string, base string) error
+	// FindPullRequest returns the number of an already-open pull request
+	// whose source is branch, if any.
+	FindPullRequest(ctx context.Context, owner string, repo string, branch string) (int, bool, error)
+	CreatePullRequest(ctx context.Context, owner string, repo string, opts PullRequestOptions) (int, error)
+	EnableAutoMerge(ctx context.Context, owner string, repo string, number int) error
+}
+
+// GithubForge implements Forge against the github.com / GitHub Enterprise
+// REST API via go-github.
+type GithubForge struct {
+	gh *github.Client
+}
+
+func NewGithubForge(gh *github.Client) *GithubForge {
+	return &GithubForge{gh: gh}
+}
+
+func (f *GithubForge) ListRepos(ctx context.Context, owner string, opts ForgeListReposOptions) (ForgeListReposResult, error) {
+	listOpts := github.ListOptions{Page: opts.Page, PerPage: opts.PerPage}
+
+	var (
+		repos []*github.Repository
+		resp  *github.Response
+		err   error
+	)
+	if opts.IsUser {
+		repos, resp, err = f.gh.Repositories.List(ctx, owner, &github.RepositoryListOptions{
+			Affiliation: "owner",
+			ListOptions: listOpts,
+		})
+	} else {
+		repos, resp, err = f.gh.Repositories.ListByOrg(ctx, owner, &github.RepositoryListByOrgOptions{
+			ListOptions: listOpts,
+		})
+	}
+	if err != nil || resp.StatusCode != 200 {
+		return ForgeListReposResult{}, fmt.Errorf("failed to list repos for %s: %s", owner, err)
+	}
+
+	infos := make([]RepoInfo, 0, len(repos))
+	for _, repo := range repos {
+		infos = append(infos, RepoInfo{Name: *repo.Name, Archived: *repo.Archived})
+	}
+	return ForgeListReposResult{Repos: infos, NextPage: resp.NextPage}, nil
+}
+
+func (f *GithubForge) GetContents(ctx context.Context, owner string, repo string, path string, ref string) (*ForgeFileContent, int, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	remoteContent, _, resp, err := f.gh.Repositories.GetContents(ctx, owner, repo, path, opts)
+	if resp.StatusCode != 200 {
+		return nil, resp.StatusCode, err
+	}
+
+	content, err := remoteContent.GetContent()
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return &ForgeFileContent{Content: content, SHA: *remoteContent.SHA}, resp.StatusCode, nil
+}
+
+func (f *GithubForge) CreateOrUpdateFile(ctx context.Context, owner string, repo string, path string, message string, content []byte, sha string, branch string) (int, error) {
+	opts := &github.RepositoryContentFileOptions{
+		Message: &message,
+		Content: content,
+	}
+	if len(sha) > 0 {
+		opts.SHA = &sha
+	}
+	if len(branch) > 0 {
+		opts.Branch = &branch
+	}
+
+	_, resp, err := f.gh.Repositories.CreateFile(ctx, owner, repo, path, opts)
+	return resp.StatusCode, err
+}
+
+func (f *GithubForge) DeleteFile(ctx context.Context, owner string, repo string, path string, message string, sha string, branch string) (int, error) {
+	opts := &github.RepositoryContentFileOptions{
+		Message: &message,
+		SHA:     &sha,
+	}
+	if len(branch) > 0 {
+		opts.Branch = &branch
+	}
+
+	_, resp, err := f.gh.Repositories.DeleteFile(ctx, owner, repo, path, opts)
+	return resp.StatusCode, err
+}
+
+func (f *GithubForge) CloneURL(owner string, repo string) string {
+	return fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
+}
+
+func (f *GithubForge) DefaultBranch(ctx context.Context, owner string, repo string) (string, error) {
+	ghRepo, _, err := f.gh.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return ghRepo.GetDefaultBranch(), nil
+}
+
+func (f *GithubForge) RepoMetadata(ctx context.Context, owner string, repo string) (RepoMetadata, error) {
+	ghRepo, _, err := f.gh.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+	return RepoMetadata{DefaultBranch: ghRepo.GetDefaultBranch(), Topics: ghRepo.Topics}, nil
+}
+
+func (f *GithubForge) EnsureBranch(ctx context.Context, owner string, repo string, branch string, base string) error {
+	_, resp, err := f.gh.Git.GetRef(ctx, owner, repo, "refs/heads/"+branch)
+	if err == nil && resp.StatusCode == 200 {
+		return nil
+	}
+
+	baseRef, _, err := f.gh.Git.GetRef(ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to look up base branch %s for %s/%s: %s", base, owner, repo, err)
+	}
+
+	_, _, err = f.gh.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: baseRef.Object,
+	})
+	return err
+}
+
+func (f *GithubForge) FindPullRequest(ctx context.Context, owner string, repo string, branch string) (int, bool, error) {
+	prs, _, err := f.gh.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+		Head:  owner + ":" + branch,
+		State: "open",
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(prs) == 0 {
+		return 0, false, nil
+	}
+	return *prs[0].Number, true, nil
+}
+
+func (f *GithubForge) CreatePullRequest(ctx context.Context, owner string, repo string, opts PullRequestOptions) (int, error) {
+	pr, _, err := f.gh.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: &opts.Title,
+		Body:  &opts.Body,
+		Head:  &opts.Head,
+		Base:  &opts.Base,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(opts.Labels) > 0 {
+		if _, _, err := f.gh.Issues.AddLabelsToIssue(ctx, owner, repo, *pr.Number, opts.Labels); err != nil {
+			return *pr.Number, err
+		}
+	}
+	if len(opts.Reviewers) > 0 {
+		reviewers := github.ReviewersRequest{Reviewers: opts.Reviewers}
+		if _, _, err := f.gh.PullRequests.RequestReviewers(ctx, owner, repo, *pr.Number, reviewers); err != nil {
+			return *pr.Number, err
+		}
+	}
+	return *pr.Number, nil
+}
+
+// EnableAutoMerge turns on auto-merge for a pull request. The REST API has
+// no endpoint for this, so it's done via the same mutation the GitHub UI
+// uses, issued over GraphQL with go-github's authenticated HTTP client.
+func (f *GithubForge) EnableAutoMerge(ctx context.Context, owner string, repo string, number int) error {
+	pr, _, err := f.gh.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"query":     `mutation($id: ID!) { enablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId } }`,
+		"variables": map[string]string{"id": pr.GetNodeID()},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.gh.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("enabling auto-merge for %s/%s#%d failed with status %d", owner, repo, number, resp.StatusCode)
+	}
+	return nil
+}
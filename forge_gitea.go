@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// GiteaForge implements Forge against a Gitea (or Forgejo) instance via the
+// official SDK client.
+type GiteaForge struct {
+	baseURL string
+	client  *gitea.Client
+}
+
+func NewGiteaForge(baseURL string, token string) (*GiteaForge, error) {
+	client, err := gitea.NewClient(baseURL, gitea.SetToken(token))
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaForge{baseURL: baseURL, client: client}, nil
+}
+
+func (f *GiteaForge) ListRepos(ctx context.Context, owner string, opts ForgeListReposOptions) (ForgeListReposResult, error) {
+	listOpts := gitea.ListOptions{Page: opts.Page, PageSize: opts.PerPage}
+
+	var (
+		repos []*gitea.Repository
+		err   error
+	)
+	if opts.IsUser {
+		repos, _, err = f.client.ListUserRepos(owner, gitea.ListReposOptions{ListOptions: listOpts})
+	} else {
+		repos, _, err = f.client.ListOrgRepos(owner, gitea.ListOrgReposOptions{ListOptions: listOpts})
+	}
+	if err != nil {
+		return ForgeListReposResult{}, fmt.Errorf("failed to list repos for %s: %s", owner, err)
+	}
+
+	infos := make([]RepoInfo, 0, len(repos))
+	for _, repo := range repos {
+		infos = append(infos, RepoInfo{Name: repo.Name, Archived: repo.Archived})
+	}
+
+	// Gitea's paginated listing doesn't echo back a "next page" indicator,
+	// so infer one from whether this page was full.
+	nextPage := 0
+	if len(repos) == opts.PerPage {
+		nextPage = opts.Page + 1
+	}
+	return ForgeListReposResult{Repos: infos, NextPage: nextPage}, nil
+}
+
+func (f *GiteaForge) GetContents(ctx context.Context, owner string, repo string, path string, ref string) (*ForgeFileContent, int, error) {
+	contents, resp, err := f.client.GetContents(owner, repo, ref, path)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, http.StatusNotFound, nil
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if contents.Content == nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("gitea returned no content for %s/%s/%s", owner, repo, path)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*contents.Content)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to decode gitea content for %s/%s/%s: %s", owner, repo, path, err)
+	}
+	return &ForgeFileContent{Content: string(decoded), SHA: contents.SHA}, http.StatusOK, nil
+}
+
+func (f *GiteaForge) CreateOrUpdateFile(ctx context.Context, owner string, repo string, path string, message string, content []byte, sha string, branch string) (int, error) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	if len(sha) > 0 {
+		_, resp, err := f.client.UpdateFile(owner, repo, path, gitea.UpdateFileOptions{
+			FileOptions: gitea.FileOptions{Message: message, BranchName: branch},
+			SHA:         sha,
+			Content:     encoded,
+		})
+		if err != nil {
+			if resp != nil && resp.Response != nil {
+				return resp.StatusCode, err
+			}
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusOK, nil
+	}
+
+	_, resp, err := f.client.CreateFile(owner, repo, path, gitea.CreateFileOptions{
+		FileOptions: gitea.FileOptions{Message: message, BranchName: branch},
+		Content:     encoded,
+	})
+	if err != nil {
+		if resp != nil && resp.Response != nil {
+			return resp.StatusCode, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+func (f *GiteaForge) DeleteFile(ctx context.Context, owner string, repo string, path string, message string, sha string, branch string) (int, error) {
+	resp, err := f.client.DeleteFile(owner, repo, path, gitea.DeleteFileOptions{
+		FileOptions: gitea.FileOptions{Message: message, BranchName: branch},
+		SHA:         sha,
+	})
+	if err != nil {
+		if resp != nil && resp.Response != nil {
+			return resp.StatusCode, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return resp.StatusCode, nil
+}
+
+func (f *GiteaForge) CloneURL(owner string, repo string) string {
+	return fmt.Sprintf("%s/%s/%s.git", f.baseURL, owner, repo)
+}
+
+func (f *GiteaForge) DefaultBranch(ctx context.Context, owner string, repo string) (string, error) {
+	giteaRepo, _, err := f.client.GetRepo(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return giteaRepo.DefaultBranch, nil
+}
+
+func (f *GiteaForge) RepoMetadata(ctx context.Context, owner string, repo string) (RepoMetadata, error) {
+	giteaRepo, _, err := f.client.GetRepo(owner, repo)
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+
+	topics, _, err := f.client.ListRepoTopics(owner, repo, gitea.ListRepoTopicsOptions{})
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+
+	return RepoMetadata{DefaultBranch: giteaRepo.DefaultBranch, Topics: topics}, nil
+}
+
+func (f *GiteaForge) EnsureBranch(ctx context.Context, owner string, repo string, branch string, base string) error {
+	if _, _, err := f.client.GetRepoBranch(owner, repo, branch); err == nil {
+		return nil
+	}
+
+	_, _, err := f.client.CreateBranch(owner, repo, gitea.CreateBranchOption{
+		BranchName:    branch,
+		OldBranchName: base,
+	})
+	return err
+}
+
+func (f *GiteaForge) FindPullRequest(ctx context.Context, owner string, repo string, branch string) (int, bool, error) {
+	prs, _, err := f.client.ListRepoPullRequests(owner, repo, gitea.ListPullRequestsOptions{
+		State: gitea.StateOpen,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	head := owner + ":" + branch
+	for _, pr := range prs {
+		if pr.Head != nil && (pr.Head.Ref == branch || pr.Head.Name == head) {
+			return int(pr.Index), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (f *GiteaForge) CreatePullRequest(ctx context.Context, owner string, repo string, opts PullRequestOptions) (int, error) {
+	labelIDs, err := f.resolveLabelIDs(owner, repo, opts.Labels)
+	if err != nil {
+		return 0, err
+	}
+
+	pr, _, err := f.client.CreatePullRequest(owner, repo, gitea.CreatePullRequestOption{
+		Head:   opts.Head,
+		Base:   opts.Base,
+		Title:  opts.Title,
+		Body:   opts.Body,
+		Labels: labelIDs,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(opts.Reviewers) > 0 {
+		if _, err := f.client.CreateReviewRequests(owner, repo, pr.Index, gitea.PullReviewRequestOptions{
+			Reviewers: opts.Reviewers,
+		}); err != nil {
+			return int(pr.Index), err
+		}
+	}
+	return int(pr.Index), nil
+}
+
+// resolveLabelIDs looks up the numeric label IDs Gitea's pull request API
+// requires, given the label names configured in PRLabels.
+func (f *GiteaForge) resolveLabelIDs(owner string, repo string, names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	repoLabels, _, err := f.client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels for %s/%s: %s", owner, repo, err)
+	}
+
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, label := range repoLabels {
+			if label.Name == name {
+				ids = append(ids, label.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("label %q does not exist on %s/%s", name, owner, repo)
+		}
+	}
+	return ids, nil
+}
+
+// EnableAutoMerge always returns an error: auto-merge is not supported for
+// the gitea forge, and CreatePullRequest does not set it either.
+func (f *GiteaForge) EnableAutoMerge(ctx context.Context, owner string, repo string, number int) error {
+	return fmt.Errorf("auto-merge is not supported for the gitea forge")
+}
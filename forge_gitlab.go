@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// GitlabForge implements Forge against gitlab.com or a self-hosted GitLab
+// instance via xanzy/go-gitlab.
+type GitlabForge struct {
+	baseURL string
+	client  *gitlab.Client
+}
+
+func NewGitlabForge(baseURL string, token string) (*GitlabForge, error) {
+	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseURL))
+	if err != nil {
+		return nil, err
+	}
+	return &GitlabForge{baseURL: baseURL, client: client}, nil
+}
+
+func (f *GitlabForge) ListRepos(ctx context.Context, owner string, opts ForgeListReposOptions) (ForgeListReposResult, error) {
+	listOpts := gitlab.ListOptions{Page: opts.Page, PerPage: opts.PerPage}
+
+	var (
+		projects []*gitlab.Project
+		resp     *gitlab.Response
+		err      error
+	)
+	if opts.IsUser {
+		projects, resp, err = f.client.Projects.ListUserProjects(owner, &gitlab.ListProjectsOptions{ListOptions: listOpts})
+	} else {
+		projects, resp, err = f.client.Groups.ListGroupProjects(owner, &gitlab.ListGroupProjectsOptions{ListOptions: listOpts})
+	}
+	if err != nil {
+		return ForgeListReposResult{}, fmt.Errorf("failed to list repos for %s: %s", owner, err)
+	}
+
+	infos := make([]RepoInfo, 0, len(projects))
+	for _, project := range projects {
+		infos = append(infos, RepoInfo{Name: project.Path, Archived: project.Archived})
+	}
+	return ForgeListReposResult{Repos: infos, NextPage: resp.NextPage}, nil
+}
+
+func (f *GitlabForge) GetContents(ctx context.Context, owner string, repo string, path string, ref string) (*ForgeFileContent, int, error) {
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+
+	pid := owner + "/" + repo
+	file, resp, err := f.client.RepositoryFiles.GetFile(pid, path, &gitlab.GetFileOptions{Ref: gitlab.Ptr(ref)})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, http.StatusNotFound, nil
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if file.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to decode gitlab content for %s/%s: %s", pid, path, err)
+		}
+		return &ForgeFileContent{Content: string(decoded), SHA: file.LastCommitID}, http.StatusOK, nil
+	}
+	return &ForgeFileContent{Content: file.Content, SHA: file.LastCommitID}, http.StatusOK, nil
+}
+
+func (f *GitlabForge) CreateOrUpdateFile(ctx context.Context, owner string, repo string, path string, message string, content []byte, sha string, branch string) (int, error) {
+	pid := owner + "/" + repo
+	contentStr := string(content)
+
+	if len(branch) == 0 {
+		defaultBranch, err := f.DefaultBranch(ctx, owner, repo)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		branch = defaultBranch
+	}
+
+	if len(sha) > 0 {
+		_, resp, err := f.client.RepositoryFiles.UpdateFile(pid, path, &gitlab.UpdateFileOptions{
+			Branch:        &branch,
+			Content:       &contentStr,
+			CommitMessage: &message,
+			LastCommitID:  &sha,
+		})
+		if err != nil {
+			if resp != nil && resp.Response != nil {
+				return resp.StatusCode, err
+			}
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusOK, nil
+	}
+
+	_, resp, err := f.client.RepositoryFiles.CreateFile(pid, path, &gitlab.CreateFileOptions{
+		Branch:        &branch,
+		Content:       &contentStr,
+		CommitMessage: &message,
+	})
+	if err != nil {
+		if resp != nil && resp.Response != nil {
+			return resp.StatusCode, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+func (f *GitlabForge) DeleteFile(ctx context.Context, owner string, repo string, path string, message string, sha string, branch string) (int, error) {
+	pid := owner + "/" + repo
+
+	if len(branch) == 0 {
+		defaultBranch, err := f.DefaultBranch(ctx, owner, repo)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		branch = defaultBranch
+	}
+
+	opts := &gitlab.DeleteFileOptions{
+		Branch:        &branch,
+		CommitMessage: &message,
+	}
+	if len(sha) > 0 {
+		opts.LastCommitID = &sha
+	}
+	resp, err := f.client.RepositoryFiles.DeleteFile(pid, path, opts)
+	if err != nil {
+		if resp != nil && resp.Response != nil {
+			return resp.StatusCode, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func (f *GitlabForge) CloneURL(owner string, repo string) string {
+	return fmt.Sprintf("%s/%s/%s.git", f.baseURL, owner, repo)
+}
+
+func (f *GitlabForge) DefaultBranch(ctx context.Context, owner string, repo string) (string, error) {
+	project, _, err := f.client.Projects.GetProject(owner+"/"+repo, nil)
+	if err != nil {
+		return "", err
+	}
+	return project.DefaultBranch, nil
+}
+
+func (f *GitlabForge) RepoMetadata(ctx context.Context, owner string, repo string) (RepoMetadata, error) {
+	project, _, err := f.client.Projects.GetProject(owner+"/"+repo, nil)
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+	return RepoMetadata{DefaultBranch: project.DefaultBranch, Topics: project.TagList}, nil
+}
+
+func (f *GitlabForge) EnsureBranch(ctx context.Context, owner string, repo string, branch string, base string) error {
+	pid := owner + "/" + repo
+	if _, _, err := f.client.Branches.GetBranch(pid, branch); err == nil {
+		return nil
+	}
+
+	_, _, err := f.client.Branches.CreateBranch(pid, &gitlab.CreateBranchOptions{
+		Branch: &branch,
+		Ref:    &base,
+	})
+	return err
+}
+
+func (f *GitlabForge) FindPullRequest(ctx context.Context, owner string, repo string, branch string) (int, bool, error) {
+	state := "opened"
+	mrs, _, err := f.client.MergeRequests.ListProjectMergeRequests(owner+"/"+repo, &gitlab.ListProjectMergeRequestsOptions{
+		State:        &state,
+		SourceBranch: &branch,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(mrs) == 0 {
+		return 0, false, nil
+	}
+	return mrs[0].IID, true, nil
+}
+
+func (f *GitlabForge) CreatePullRequest(ctx context.Context, owner string, repo string, opts PullRequestOptions) (int, error) {
+	reviewerIDs, err := f.resolveReviewerIDs(opts.Reviewers)
+	if err != nil {
+		return 0, err
+	}
+
+	mr, _, err := f.client.MergeRequests.CreateMergeRequest(owner+"/"+repo, &gitlab.CreateMergeRequestOptions{
+		Title:        &opts.Title,
+		Description:  &opts.Body,
+		SourceBranch: &opts.Head,
+		TargetBranch: &opts.Base,
+		Labels:       (*gitlab.LabelOptions)(&opts.Labels),
+		ReviewerIDs:  &reviewerIDs,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return mr.IID, nil
+}
+
+// resolveReviewerIDs looks up the numeric user IDs GitLab's merge request API
+// requires, given the usernames configured in PRReviewers.
+func (f *GitlabForge) resolveReviewerIDs(usernames []string) ([]int, error) {
+	ids := make([]int, 0, len(usernames))
+	for _, username := range usernames {
+		users, _, err := f.client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(username)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up gitlab user %q: %s", username, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("gitlab user %q does not exist", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// EnableAutoMerge asks GitLab to merge the request itself as soon as the
+// pipeline succeeds, which is the closest equivalent GitLab offers.
+func (f *GitlabForge) EnableAutoMerge(ctx context.Context, owner string, repo string, number int) error {
+	mergeWhenPipelineSucceeds := true
+	_, _, err := f.client.MergeRequests.AcceptMergeRequest(owner+"/"+repo, number, &gitlab.AcceptMergeRequestOptions{
+		MergeWhenPipelineSucceeds: &mergeWhenPipelineSucceeds,
+	})
+	return err
+}
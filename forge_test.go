@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestNewForgesGithubOnly(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	forges, err := NewForges(Config{
+		Remote: map[string]RemoteSpec{
+			"main": {Org: "octocat"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewForges returned an error: %s", err)
+	}
+
+	if _, ok := forges["github"]; !ok {
+		t.Errorf("expected a github forge, got %v", forges)
+	}
+	if len(forges) != 1 {
+		t.Errorf("expected exactly one forge, got %v", forges)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gofri/go-github-ratelimit/github_ratelimit"
@@ -16,14 +19,29 @@ import (
 type Config struct {
 	Remote map[string]RemoteSpec
 	File   map[string]FileSpec
+	// PerRepoTimeout bounds how long processing a single repo may take,
+	// expressed in nanoseconds since BurntSushi/toml decodes TOML integers
+	// straight into time.Duration fields (e.g. 30s is 30000000000). Zero
+	// means no per-repo timeout, only the process-wide cancellation from
+	// Ctrl+C/SIGTERM.
+	PerRepoTimeout time.Duration `toml:"per_repo_timeout"`
 }
 
 type RemoteSpec struct {
-	Org          string
-	User         string
-	Repo         string
+	Org  string
+	User string
+	Repo string
+	// Repos lists repos directly, as an alternative to Repo (a single repo)
+	// or RepoGlob (a pattern matched against the org/user's repo listing).
+	Repos        []string `toml:"repos"`
 	RepoGlob     string   `toml:"repo_glob"`
 	ExcludeRepos []string `toml:"exclude_repos"`
+	// Forge selects which backend this remote is hosted on: "github"
+	// (default), "gitea", or "gitlab".
+	Forge string
+	// BaseURL points the forge client at a self-hosted instance. Ignored
+	// for the default "github" forge, which always talks to github.com.
+	BaseURL string `toml:"base_url"`
 }
 
 func (rs *RemoteSpec) Owner() string {
@@ -34,10 +52,98 @@ func (rs *RemoteSpec) Owner() string {
 	}
 }
 
+// ForgeName returns the configured Forge, defaulting to "github" so existing
+// configs that don't set it keep working unchanged.
+func (rs *RemoteSpec) ForgeName() string {
+	if len(rs.Forge) > 0 {
+		return rs.Forge
+	}
+	return "github"
+}
+
 type FileSpec struct {
 	Path    string
 	Dest    string
 	Remotes []string
+	// LFS tracks the destination path with git-lfs when a file must be
+	// pushed via the git worktree fallback (see updateFileViaGit).
+	LFS bool
+	// Mode controls how a change reaches the repo: "direct" (default) commits
+	// straight to the default branch, "branch" commits to a dedicated branch
+	// without opening anything, and "pull_request" additionally opens (or
+	// reuses) a pull request for review.
+	Mode string
+	// Branch overrides the branch used by "branch" and "pull_request" mode.
+	// Defaults to a name derived from Dest so repeated runs reuse it.
+	Branch string
+	// PRTitle/PRBody template the pull request opened in "pull_request" mode.
+	PRTitle     string   `toml:"pr_title"`
+	PRBody      string   `toml:"pr_body"`
+	PRLabels    []string `toml:"pr_labels"`
+	PRReviewers []string `toml:"pr_reviewers"`
+	// Template renders Path's contents, and Dest itself, through
+	// text/template against a TemplateContext before comparing/uploading,
+	// so a single FileSpec can vary per repo or fan out to per-repo paths.
+	Template bool
+	Vars     map[string]string
+	// State controls whether Dest should exist: "present" (default) creates
+	// or updates it, "absent" deletes it if it's there and no-ops otherwise.
+	State string
+	// RenamedFrom, when set, moves a file from this path to Dest in a single
+	// commit instead of just creating/updating Dest in place.
+	RenamedFrom string `toml:"renamed_from"`
+}
+
+// ModeName returns the configured Mode, defaulting to "direct" so existing
+// configs that don't set it keep working unchanged.
+func (fs *FileSpec) ModeName() string {
+	if len(fs.Mode) > 0 {
+		return fs.Mode
+	}
+	return "direct"
+}
+
+// StateName returns the configured State, defaulting to "present" so
+// existing configs that don't set it keep working unchanged.
+func (fs *FileSpec) StateName() string {
+	if len(fs.State) > 0 {
+		return fs.State
+	}
+	return "present"
+}
+
+func (fs *FileSpec) prTitle(dest string) string {
+	if len(fs.PRTitle) > 0 {
+		return fs.PRTitle
+	}
+	if len(fs.RenamedFrom) > 0 {
+		return fmt.Sprintf("FileMaintainer: Rename %s to %s", fs.RenamedFrom, dest)
+	}
+	return fmt.Sprintf("FileMaintainer: Create or Update %s", dest)
+}
+
+func (fs *FileSpec) prBody(dest string) string {
+	if len(fs.PRBody) > 0 {
+		return fs.PRBody
+	}
+	if len(fs.RenamedFrom) > 0 {
+		return fmt.Sprintf("This PR was opened by FileMaintainer to rename %s to %s.", fs.RenamedFrom, dest)
+	}
+	return fmt.Sprintf("This PR was opened by FileMaintainer to keep %s in sync.", dest)
+}
+
+func (fs *FileSpec) prDeleteTitle(dest string) string {
+	if len(fs.PRTitle) > 0 {
+		return fs.PRTitle
+	}
+	return fmt.Sprintf("FileMaintainer: Delete %s", dest)
+}
+
+func (fs *FileSpec) prDeleteBody(dest string) string {
+	if len(fs.PRBody) > 0 {
+		return fs.PRBody
+	}
+	return fmt.Sprintf("This PR was opened by FileMaintainer to remove %s.", dest)
 }
 
 func main() {
@@ -45,6 +151,9 @@ func main() {
 	dryRun := flag.Bool("dry-run", true, "")
 	debug := flag.Bool("debug", false, "")
 	onlyRepo := flag.String("only-repo", "", "Update this repository only")
+	autoMerge := flag.Bool("auto-merge", false, "Enable auto-merge on pull requests opened in pull_request mode")
+	concurrency := flag.Int("concurrency", 8, "Number of repos to process at once")
+	failFast := flag.Bool("fail-fast", false, "Abort on the first repo that fails instead of aggregating all errors")
 	flag.Parse()
 
 	logger, err := NewLogger(*debug)
@@ -60,15 +169,21 @@ func main() {
 		panic(err)
 	}
 
-	err = validateConfig(config)
+	err = validateConfig(config, *concurrency)
 	if err != nil {
 		panic(err)
 	}
 
-	gh := NewGH()
+	forges, err := NewForges(config)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	processor := NewProcessor(*dryRun, *onlyRepo, gh, sugar)
-	err = processor.ProcessFiles(config)
+	processor := NewProcessor(*dryRun, *onlyRepo, *autoMerge, *concurrency, *failFast, config.PerRepoTimeout, forges, sugar)
+	err = processor.ProcessFiles(ctx, config)
 	if err != nil {
 		panic(err)
 	}
@@ -106,7 +221,54 @@ func NewGH() *github.Client {
 	return gh
 }
 
-func validateConfig(config Config) error {
+// NewForges builds one Forge client per distinct forge referenced by
+// config.Remote, keyed by forge name, so a single run can maintain files
+// across mixed providers. When several remotes use the same forge with
+// different BaseURLs, only one of those BaseURLs is used, and which one is
+// not defined since config.Remote is a map.
+func NewForges(config Config) (map[string]Forge, error) {
+	baseURLs := make(map[string]string)
+	for _, remote := range config.Remote {
+		if _, ok := baseURLs[remote.ForgeName()]; !ok {
+			baseURLs[remote.ForgeName()] = remote.BaseURL
+		}
+	}
+
+	forges := make(map[string]Forge)
+	for forgeName, baseURL := range baseURLs {
+		switch forgeName {
+		case "github":
+			forges["github"] = NewGithubForge(NewGH())
+		case "gitea":
+			forge, err := NewGiteaForge(baseURL, mustEnv("GITEA_TOKEN"))
+			if err != nil {
+				return nil, err
+			}
+			forges["gitea"] = forge
+		case "gitlab":
+			forge, err := NewGitlabForge(baseURL, mustEnv("GITLAB_TOKEN"))
+			if err != nil {
+				return nil, err
+			}
+			forges["gitlab"] = forge
+		}
+	}
+	return forges, nil
+}
+
+func mustEnv(name string) string {
+	value, hasValue := os.LookupEnv(name)
+	if !hasValue {
+		panic(fmt.Sprintf("Must have a %s environment variable.", name))
+	}
+	return value
+}
+
+func validateConfig(config Config, concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+	}
+
 	err := validateRemotes(config.Remote)
 	if err != nil {
 		return err
@@ -118,26 +280,46 @@ func validateConfig(config Config) error {
 
 // Valid remotes:
 //   - Have either an org or a user
+//   - Have a recognized forge, if one is set
 func validateRemotes(remoteSpec map[string]RemoteSpec) error {
 	for name, remote := range remoteSpec {
 		if (len(remote.Org) == 0) == (len(remote.User) == 0) {
 			return fmt.Errorf("remote %s must have either an org or a user", name)
 		}
+
+		switch remote.ForgeName() {
+		case "github", "gitea", "gitlab":
+		default:
+			return fmt.Errorf("remote %s has an unrecognized forge %q", name, remote.Forge)
+		}
 	}
 	return nil
 }
 
 // Valid files:
-//   - Point to real files
+//   - Point to real files, unless they're being deleted
 //   - Have a destination path
 //   - Reference valid remotes
+//   - Have a recognized mode and state, if set
 func validateFiles(config Config) error {
 	for name, file := range config.File {
-		// Points to a real file
-		if !isFile(file.Path) {
+		switch file.StateName() {
+		case "present", "absent":
+		default:
+			return fmt.Errorf("file %s has an unrecognized state %q", name, file.State)
+		}
+
+		// Points to a real file, unless it's only being removed
+		if file.StateName() == "present" && !isFile(file.Path) {
 			return fmt.Errorf("file %s must have a path which exists and is a file", name)
 		}
 
+		switch file.ModeName() {
+		case "direct", "branch", "pull_request":
+		default:
+			return fmt.Errorf("file %s has an unrecognized mode %q", name, file.Mode)
+		}
+
 		// Has a destination path
 		if len(file.Dest) == 0 {
 			return fmt.Errorf("file %s must have a dest", name)
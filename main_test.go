@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestValidateConfigRejectsNonPositiveConcurrency(t *testing.T) {
+	if err := validateConfig(Config{}, 0); err == nil {
+		t.Error("validateConfig with concurrency 0 should have returned an error")
+	}
+	if err := validateConfig(Config{}, -1); err == nil {
+		t.Error("validateConfig with concurrency -1 should have returned an error")
+	}
+	if err := validateConfig(Config{}, 1); err != nil {
+		t.Errorf("validateConfig with concurrency 1 should not have errored, got %s", err)
+	}
+}
+
+func TestFileSpecModeNameDefault(t *testing.T) {
+	if got := (&FileSpec{}).ModeName(); got != "direct" {
+		t.Errorf("ModeName() = %q, want %q", got, "direct")
+	}
+	if got := (&FileSpec{Mode: "pull_request"}).ModeName(); got != "pull_request" {
+		t.Errorf("ModeName() = %q, want %q", got, "pull_request")
+	}
+}
+
+func TestFileSpecStateNameDefault(t *testing.T) {
+	if got := (&FileSpec{}).StateName(); got != "present" {
+		t.Errorf("StateName() = %q, want %q", got, "present")
+	}
+	if got := (&FileSpec{State: "absent"}).StateName(); got != "absent" {
+		t.Errorf("StateName() = %q, want %q", got, "absent")
+	}
+}
+
+func TestRemoteSpecOwner(t *testing.T) {
+	org := RemoteSpec{Org: "octocat"}
+	if got := org.Owner(); got != "octocat" {
+		t.Errorf("Owner() = %q, want %q", got, "octocat")
+	}
+
+	user := RemoteSpec{User: "octocat"}
+	if got := user.Owner(); got != "octocat" {
+		t.Errorf("Owner() = %q, want %q", got, "octocat")
+	}
+}
@@ -2,35 +2,64 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/google/go-github/v52/github"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/hashicorp/go-multierror"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 type Processor struct {
-	dryRun        bool
-	onlyRepo      string
-	gh            *github.Client
-	resolver      *RemoteResolver
-	remoteSpecMap map[string]RemoteSpec
-	logger        *zap.SugaredLogger
+	dryRun         bool
+	onlyRepo       string
+	autoMerge      bool
+	concurrency    int
+	failFast       bool
+	perRepoTimeout time.Duration
+	forges         map[string]Forge
+	resolver       *RemoteResolver
+	remoteSpecMap  map[string]RemoteSpec
+	logger         *zap.SugaredLogger
 }
 
-func NewProcessor(dryRun bool, onlyRepo string, gh *github.Client, logger *zap.SugaredLogger) *Processor {
+func NewProcessor(dryRun bool, onlyRepo string, autoMerge bool, concurrency int, failFast bool, perRepoTimeout time.Duration, forges map[string]Forge, logger *zap.SugaredLogger) *Processor {
 	return &Processor{
-		dryRun:        dryRun,
-		onlyRepo:      onlyRepo,
-		gh:            gh,
-		logger:        logger,
-		remoteSpecMap: make(map[string]RemoteSpec),
-		resolver:      NewRemoteResolver(gh, logger),
+		dryRun:         dryRun,
+		onlyRepo:       onlyRepo,
+		autoMerge:      autoMerge,
+		concurrency:    concurrency,
+		failFast:       failFast,
+		perRepoTimeout: perRepoTimeout,
+		forges:         forges,
+		logger:         logger,
+		remoteSpecMap:  make(map[string]RemoteSpec),
+		resolver:       NewRemoteResolver(logger),
 	}
 }
 
+// forgeFor looks up the Forge a remote talks to, keyed by RemoteSpec.ForgeName().
+func (p *Processor) forgeFor(remote RemoteSpec) (Forge, error) {
+	forge, ok := p.forges[remote.ForgeName()]
+	if !ok {
+		return nil, fmt.Errorf("no forge configured for %q", remote.ForgeName())
+	}
+	return forge, nil
+}
+
 func (p *Processor) updateRemoteSpecMap(config Config) {
 	for name, remote := range config.Remote {
 		if _, ok := p.remoteSpecMap[name]; !ok {
@@ -39,10 +68,10 @@ func (p *Processor) updateRemoteSpecMap(config Config) {
 	}
 }
 
-func (p *Processor) ProcessFiles(config Config) error {
+func (p *Processor) ProcessFiles(ctx context.Context, config Config) error {
 	p.updateRemoteSpecMap(config)
 	for _, file := range config.File {
-		err := p.ProcessFile(file, config)
+		err := p.ProcessFile(ctx, file, config)
 		if err != nil {
 			return err
 		}
@@ -50,7 +79,7 @@ func (p *Processor) ProcessFiles(config Config) error {
 	return nil
 }
 
-func (p *Processor) ProcessFile(file FileSpec, config Config) error {
+func (p *Processor) ProcessFile(ctx context.Context, file FileSpec, config Config) error {
 	p.logger.Debugf("processing file %s", file.Dest)
 	for _, remoteName := range file.Remotes {
 		remote, ok := config.Remote[remoteName]
@@ -58,34 +87,33 @@ func (p *Processor) ProcessFile(file FileSpec, config Config) error {
 			return fmt.Errorf("did not find a remote named %s in remotes", remoteName)
 		}
 
-		content, err := os.ReadFile(file.Path)
+		forge, err := p.forgeFor(remote)
 		if err != nil {
 			return err
 		}
 
-		err = p.applyToAllRepos(remote, remoteName, func(owner string, repo string) error {
-			remoteContentResp, _, resp, err := p.gh.Repositories.GetContents(context.Background(), owner, repo, file.Dest, &github.RepositoryContentGetOptions{})
-			if resp.StatusCode == 200 {
-				// Avoid an update if the remote content doesn't need to change
-				remoteContent, err := remoteContentResp.GetContent()
-				if err == nil && remoteContent == string(content) {
-					p.logger.Debugf("skipping %s/%s/%s because it does not need to be updated", owner, repo, file.Dest)
-					return nil
-				}
+		var rawContent []byte
+		if file.StateName() == "present" {
+			rawContent, err = os.ReadFile(file.Path)
+			if err != nil {
+				return err
+			}
+		}
 
-				err = p.updateFile(owner, repo, file.Dest, content, *remoteContentResp.SHA)
-				if err != nil {
-					return err
-				}
-			} else if resp.StatusCode == 404 {
-				err = p.createFile(owner, repo, file.Dest, content)
-				if err != nil {
-					return err
-				}
-			} else {
-				return fmt.Errorf("failed to fetch contents of file %s/%s/%s: %s", owner, repo, file.Dest, err)
+		err = p.applyToAllRepos(ctx, forge, remote, remoteName, func(ctx context.Context, owner string, repo string, resolved *ResolvedRemote) error {
+			dest, oldPath, content, err := p.render(ctx, forge, resolved, repo, file, rawContent)
+			if err != nil {
+				return err
+			}
+
+			switch file.StateName() {
+			case "present":
+				return p.applyPresent(ctx, forge, owner, repo, dest, oldPath, file, content)
+			case "absent":
+				return p.applyAbsent(ctx, forge, owner, repo, dest, file)
+			default:
+				return fmt.Errorf("file %s has an unrecognized state %q", file.Dest, file.State)
 			}
-			return nil
 		})
 		if err != nil {
 			return err
@@ -94,25 +122,188 @@ func (p *Processor) ProcessFile(file FileSpec, config Config) error {
 	return nil
 }
 
-func (p *Processor) updateFile(owner string, repo string, dest string, content []byte, sha string) error {
+// render resolves the destination path, the source path being renamed from
+// (if any), and the content that should actually be written to repo,
+// rendering all three through text/template against repo's TemplateContext
+// when file.Template is set.
+func (p *Processor) render(ctx context.Context, forge Forge, resolved *ResolvedRemote, repo string, file FileSpec, rawContent []byte) (string, string, []byte, error) {
+	if !file.Template {
+		return file.Dest, file.RenamedFrom, rawContent, nil
+	}
+
+	tmplCtx, err := p.templateContext(ctx, forge, resolved, repo, file.Vars)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	dest, err := renderTemplate("dest", file.Dest, tmplCtx)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	oldPath := file.RenamedFrom
+	if len(oldPath) > 0 {
+		oldPath, err = renderTemplate("renamed_from", oldPath, tmplCtx)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	content, err := renderTemplate("content", string(rawContent), tmplCtx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return dest, oldPath, []byte(content), nil
+}
+
+// applyPresent makes sure dest exists with content, in whichever way
+// file.ModeName() calls for. When oldPath is set, dest is reached by
+// renaming oldPath to dest rather than writing dest in place.
+func (p *Processor) applyPresent(ctx context.Context, forge Forge, owner string, repo string, dest string, oldPath string, file FileSpec, content []byte) error {
+	switch file.ModeName() {
+	case "direct":
+		if len(oldPath) > 0 {
+			_, err := p.applyRename(ctx, forge, owner, repo, oldPath, dest, file, content, "")
+			return err
+		}
+		return p.applyDirect(ctx, forge, owner, repo, dest, file, content)
+	case "branch":
+		branch := branchNameForFile(file, dest)
+		if !p.dryRun {
+			base, err := forge.DefaultBranch(ctx, owner, repo)
+			if err != nil {
+				return err
+			}
+			if err := forge.EnsureBranch(ctx, owner, repo, branch, base); err != nil {
+				return err
+			}
+		}
+		if len(oldPath) > 0 {
+			_, err := p.applyRename(ctx, forge, owner, repo, oldPath, dest, file, content, branch)
+			return err
+		}
+		_, err := p.applyToBranch(ctx, forge, owner, repo, dest, file, content, branch)
+		return err
+	case "pull_request":
+		return p.applyAsPullRequest(ctx, forge, owner, repo, dest, oldPath, file, content)
+	default:
+		return fmt.Errorf("file %s has an unrecognized mode %q", dest, file.Mode)
+	}
+}
+
+// applyDirect is the original behavior: commit straight to the repo's
+// default branch.
+func (p *Processor) applyDirect(ctx context.Context, forge Forge, owner string, repo string, dest string, file FileSpec, content []byte) error {
+	_, err := p.applyToBranch(ctx, forge, owner, repo, dest, file, content, "")
+	return err
+}
+
+// applyToBranch creates or updates dest on branch (the default branch when
+// branch is empty), returning whether a commit was actually made.
+func (p *Processor) applyToBranch(ctx context.Context, forge Forge, owner string, repo string, dest string, file FileSpec, content []byte, branch string) (bool, error) {
+	remoteContent, statusCode, err := forge.GetContents(ctx, owner, repo, dest, branch)
+	if statusCode == 200 {
+		// Avoid an update if the remote content doesn't need to change
+		if remoteContent.Content == string(content) {
+			p.logger.Debugf("skipping %s/%s/%s because it does not need to be updated", owner, repo, dest)
+			return false, nil
+		}
+
+		return true, p.updateFile(ctx, forge, owner, repo, dest, content, remoteContent.SHA, file.LFS, branch)
+	} else if statusCode == 404 {
+		return true, p.createFile(ctx, forge, owner, repo, dest, content, file.LFS, branch)
+	}
+	return false, fmt.Errorf("failed to fetch contents of file %s/%s/%s: %s", owner, repo, dest, err)
+}
+
+// applyAsPullRequest ensures a dedicated branch carries the desired content
+// (or, when oldPath is set, carries out the rename), then opens (or reuses)
+// a pull request for it.
+func (p *Processor) applyAsPullRequest(ctx context.Context, forge Forge, owner string, repo string, dest string, oldPath string, file FileSpec, content []byte) error {
+	branch := branchNameForFile(file, dest)
+	if p.dryRun {
+		if len(oldPath) > 0 {
+			p.logger.Infof("would ensure branch %s and open a pull request to rename %s to %s in %s/%s", branch, oldPath, dest, owner, repo)
+		} else {
+			p.logger.Infof("would ensure branch %s and open a pull request for %s/%s/%s", branch, owner, repo, dest)
+		}
+		return nil
+	}
+
+	base, err := forge.DefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := forge.EnsureBranch(ctx, owner, repo, branch, base); err != nil {
+		return err
+	}
+
+	var changed bool
+	if len(oldPath) > 0 {
+		changed, err = p.applyRename(ctx, forge, owner, repo, oldPath, dest, file, content, branch)
+	} else {
+		changed, err = p.applyToBranch(ctx, forge, owner, repo, dest, file, content, branch)
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		p.logger.Debugf("skipping pull request for %s/%s/%s because %s already matches", owner, repo, dest, branch)
+		return nil
+	}
+
+	number, found, err := forge.FindPullRequest(ctx, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+	if !found {
+		number, err = forge.CreatePullRequest(ctx, owner, repo, PullRequestOptions{
+			Title:     file.prTitle(dest),
+			Body:      file.prBody(dest),
+			Head:      branch,
+			Base:      base,
+			Labels:    file.PRLabels,
+			Reviewers: file.PRReviewers,
+		})
+		if err != nil {
+			return err
+		}
+		p.logger.Infof("opened pull request #%d for %s/%s/%s", number, owner, repo, dest)
+	} else {
+		p.logger.Infof("updated existing pull request #%d for %s/%s/%s", number, owner, repo, dest)
+	}
+
+	if p.autoMerge {
+		if err := forge.EnableAutoMerge(ctx, owner, repo, number); err != nil {
+			p.logger.Debugf("could not enable auto-merge for %s/%s#%d: %s", owner, repo, number, err)
+		}
+	}
+	return nil
+}
+
+// branchNameForFile picks the branch a "branch" or "pull_request" mode
+// commits to: an explicit override, or a name derived from dest so repeated
+// runs land on the same branch.
+func branchNameForFile(file FileSpec, dest string) string {
+	if len(file.Branch) > 0 {
+		return file.Branch
+	}
+	sum := sha256.Sum256([]byte(dest))
+	return fmt.Sprintf("filemaintainer/%x", sum[:6])
+}
+
+func (p *Processor) updateFile(ctx context.Context, forge Forge, owner string, repo string, dest string, content []byte, sha string, lfs bool, branch string) error {
 	if p.dryRun {
 		p.logger.Infof("would create or update file %s/%s/%s", owner, repo, dest)
 		return nil
 	}
 
 	msg := fmt.Sprintf("FileMaintainer: Create or Update %s", dest)
-	_, resp, err := p.gh.Repositories.CreateFile(context.Background(),
-		owner,
-		repo,
-		dest,
-		&github.RepositoryContentFileOptions{
-			Message: &msg,
-			Content: content,
-			SHA:     &sha,
-		})
-	if resp.StatusCode == 409 {
+	statusCode, err := forge.CreateOrUpdateFile(ctx, owner, repo, dest, msg, content, sha, branch)
+	if statusCode == 409 {
 		p.logger.Debugf("could not update file via API due to conflict (will try git-based update): %s", err)
-		return p.updateFileViaGit(owner, repo, dest, content)
+		return p.updateFileViaGit(ctx, forge, owner, repo, dest, content, lfs, branch)
 	} else {
 		if err != nil {
 			return err
@@ -122,24 +313,17 @@ func (p *Processor) updateFile(owner string, repo string, dest string, content [
 	}
 }
 
-func (p *Processor) createFile(owner string, repo string, dest string, content []byte) error {
+func (p *Processor) createFile(ctx context.Context, forge Forge, owner string, repo string, dest string, content []byte, lfs bool, branch string) error {
 	if p.dryRun {
 		p.logger.Infof("would create or update file %s/%s/%s", owner, repo, dest)
 		return nil
 	}
 
 	msg := fmt.Sprintf("FileMaintainer: Create or Update %s", dest)
-	_, resp, err := p.gh.Repositories.CreateFile(context.Background(),
-		owner,
-		repo,
-		dest,
-		&github.RepositoryContentFileOptions{
-			Message: &msg,
-			Content: content,
-		})
-	if resp.StatusCode == 409 {
+	statusCode, err := forge.CreateOrUpdateFile(ctx, owner, repo, dest, msg, content, "", branch)
+	if statusCode == 409 {
 		p.logger.Debugf("could not update file via API due to conflict (will try git-based update): %s", err)
-		return p.updateFileViaGit(owner, repo, dest, content)
+		return p.updateFileViaGit(ctx, forge, owner, repo, dest, content, lfs, branch)
 	} else {
 		if err != nil {
 			return err
@@ -149,87 +333,561 @@ func (p *Processor) createFile(owner string, repo string, dest string, content [
 	}
 }
 
-func (p *Processor) updateFileViaGit(owner string, repo string, dest string, content []byte) error {
+// updateFileViaGit performs the clone/commit/push itself, so ctx only bounds
+// it cooperatively: it's checked before the clone starts, but go-git's clone,
+// commit, and push calls below don't accept a context to cancel mid-flight.
+func (p *Processor) updateFileViaGit(ctx context.Context, forge Forge, owner string, repo string, dest string, content []byte, lfs bool, branch string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if p.dryRun {
 		p.logger.Infof("would create or update file %s/%s/%s", owner, repo, dest)
 		return nil
 	}
 
-	dir, err := p.cloneRepo(owner, repo)
+	auth, err := resolveGitAuth()
+	if err != nil {
+		return err
+	}
+
+	gitRepo, dir, err := p.cloneRepo(forge, owner, repo, auth, branch)
 	p.logger.Debugf("cloned %s/%s to %s: %s", owner, repo, dir, err)
 	if err != nil {
 		return err
 	}
 
-	err = p.writeFileToRepo(dir, dest, content)
+	if lfs {
+		if err := enableLFS(dir, dest); err != nil {
+			return err
+		}
+	}
+
+	err = p.writeFileToRepo(gitRepo, dir, dest, content, lfs)
 	if err != nil {
 		return err
 	}
 
 	p.logger.Debugf("pushing %s", dir)
-	err = p.pushRepo(dir)
+	err = p.pushRepo(gitRepo, dir, auth, lfs)
 	p.logger.Debugf("pushed %s: %s", dir, err)
 	return err
 }
 
-func (p *Processor) cloneRepo(owner string, repo string) (string, error) {
+// resolveGitAuth picks an auth method for go-git based on the environment: a
+// GITHUB_TOKEN is preferred, falling back to an SSH key pointed to by
+// GIT_SSH_KEY_PATH (optionally protected by GIT_SSH_KEY_PASSPHRASE).
+func resolveGitAuth() (transport.AuthMethod, error) {
+	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+		return &githttp.BasicAuth{Username: "x-access-token", Password: token}, nil
+	}
+
+	if keyPath, ok := os.LookupEnv("GIT_SSH_KEY_PATH"); ok {
+		passphrase := os.Getenv("GIT_SSH_KEY_PASSPHRASE")
+		return gitssh.NewPublicKeysFromFile("git", keyPath, passphrase)
+	}
+
+	return nil, fmt.Errorf("must have a GITHUB_TOKEN or GIT_SSH_KEY_PATH environment variable to push via git")
+}
+
+// gitCLIAuth mirrors resolveGitAuth for commands run through the real git
+// binary (see runGitCLI): it returns the extra global arguments and/or
+// environment variables needed to authenticate, since the real git commands
+// LFS pushes require can't take a go-git transport.AuthMethod directly.
+func gitCLIAuth() (args []string, env []string, err error) {
+	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+		header := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		return []string{"-c", "http.extraheader=Authorization: Basic " + header}, nil, nil
+	}
+
+	if keyPath, ok := os.LookupEnv("GIT_SSH_KEY_PATH"); ok {
+		return nil, []string{"GIT_SSH_COMMAND=ssh -i " + keyPath + " -o IdentitiesOnly=yes"}, nil
+	}
+
+	return nil, nil, fmt.Errorf("must have a GITHUB_TOKEN or GIT_SSH_KEY_PATH environment variable to push via git")
+}
+
+// runGitCLI runs the real git binary in dir, authenticated the same way
+// gitCLIAuth's caller resolved. It's used instead of go-git wherever a
+// command needs git's clean/smudge filters to actually fire (LFS tracking),
+// since go-git's Worktree/Push never invoke them.
+func runGitCLI(dir string, args ...string) error {
+	authArgs, authEnv, err := gitCLIAuth()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", append(authArgs, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), authEnv...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %v failed: %s: %s", args, err, out)
+	}
+	return nil
+}
+
+// cloneRepo clones owner/repo, checking out branch directly (the default
+// branch when branch is empty). branch must already exist remotely, which
+// "pull_request" mode guarantees via forge.EnsureBranch before cloning.
+func (p *Processor) cloneRepo(forge Forge, owner string, repo string, auth transport.AuthMethod, branch string) (*git.Repository, string, error) {
 	dir := path.Join(os.TempDir(), "FileMaintainer", "clones", owner, repo)
 	if err := os.RemoveAll(dir); err != nil {
-		return "", err
+		return nil, "", err
 	}
 	if err := os.MkdirAll(path.Dir(dir), 0777); err != nil {
-		return "", err
+		return nil, "", err
+	}
+
+	opts := &git.CloneOptions{
+		URL:   forge.CloneURL(owner, repo),
+		Auth:  auth,
+		Depth: 1,
 	}
-	ref := fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
-	cmd := exec.Command("git", "clone", "--depth=1", "--", ref, dir)
+	if len(branch) > 0 {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+		opts.SingleBranch = true
+	}
+
+	gitRepo, err := git.PlainClone(dir, false, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return gitRepo, dir, nil
+}
+
+// enableLFS shells out to the git-lfs CLI since go-git does not speak the LFS
+// protocol. It is only invoked when a FileSpec opts in via its LFS field.
+func enableLFS(dir string, dest string) error {
+	cmd := exec.Command("git", "lfs", "install")
+	cmd.Dir = dir
 	if err := cmd.Run(); err != nil {
-		return "", err
+		return err
 	}
-	return dir, nil
+
+	cmd = exec.Command("git", "lfs", "track", dest)
+	cmd.Dir = dir
+	return cmd.Run()
 }
 
-func (p *Processor) writeFileToRepo(dir string, dest string, content []byte) error {
+// writeFileToRepo writes dest and commits it. When lfs is set, the add and
+// commit are done through the real git binary instead of go-git: go-git's
+// Worktree.Add/Commit never invoke git's clean/smudge filters, so a go-git
+// commit of an LFS-tracked path would write the file into history as plain
+// content instead of an LFS pointer. Going through git CLI also lets us
+// commit alongside dest the .gitattributes change enableLFS made, which
+// go-git would otherwise leave uncommitted and the tracking declaration
+// would be lost.
+func (p *Processor) writeFileToRepo(gitRepo *git.Repository, dir string, dest string, content []byte, lfs bool) error {
 	fullpath := path.Join(dir, dest)
 	if err := os.WriteFile(fullpath, content, 0777); err != nil {
 		return err
 	}
 
-	cmd := exec.Command("git", "add", "--", fullpath)
-	cmd.Dir = dir
-	if err := cmd.Run(); err != nil {
+	msg := fmt.Sprintf("FileMaintainer: Create or Update %s", dest)
+	if lfs {
+		return commitViaGitCLI(dir, []string{dest, ".gitattributes"}, msg)
+	}
+
+	wt, err := gitRepo.Worktree()
+	if err != nil {
 		return err
 	}
 
-	msg := fmt.Sprintf("FileMaintainer: Create or Update %s", dest)
-	cmd = exec.Command("git", "commit", "-m", msg)
-	cmd.Dir = dir
-	if err := cmd.Run(); err != nil {
+	if _, err := wt.Add(dest); err != nil {
 		return err
 	}
 
-	return nil
+	_, err = wt.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "FileMaintainer",
+			Email: "filemaintainer@users.noreply.github.com",
+			When:  time.Now(),
+		},
+	})
+	return err
 }
 
-func (p *Processor) pushRepo(dir string) error {
-	cmd := exec.Command("git", "push")
-	cmd.Dir = dir
-	return cmd.Run()
+// commitViaGitCLI stages paths and commits them through the real git binary,
+// so any clean/smudge filters git-lfs wired up via enableLFS actually fire.
+func commitViaGitCLI(dir string, paths []string, msg string) error {
+	if err := runGitCLI(dir, append([]string{"add"}, paths...)...); err != nil {
+		return err
+	}
+	return runGitCLI(dir,
+		"-c", "user.name=FileMaintainer",
+		"-c", "user.email=filemaintainer@users.noreply.github.com",
+		"commit", "-m", msg)
 }
 
-func (p *Processor) applyToAllRepos(remote RemoteSpec, remoteName string, f func(owner string, repo string) error) error {
-	resolved, err := p.resolver.ResolveRemote(remote, remoteName)
-	p.logger.Debugf("resolved %s as %+v %s", remote, resolved, err)
+// pushRepo pushes dir to its remote. When lfs is set, this goes through the
+// real git binary (see writeFileToRepo) so git-lfs's pre-push hook actually
+// uploads the large objects the commit's pointer files reference; go-git's
+// Push has no concept of the LFS protocol and would only push the pointers.
+func (p *Processor) pushRepo(gitRepo *git.Repository, dir string, auth transport.AuthMethod, lfs bool) error {
+	if lfs {
+		return runGitCLI(dir, "push")
+	}
+	return gitRepo.Push(&git.PushOptions{Auth: auth})
+}
+
+// applyRename moves oldPath to dest on branch (the default branch when
+// branch is empty), returning whether a commit was actually made. The
+// Contents API can't change two paths atomically, so this always goes
+// through the git worktree, unlike applyToBranch's API-first/git-fallback
+// split.
+func (p *Processor) applyRename(ctx context.Context, forge Forge, owner string, repo string, oldPath string, dest string, file FileSpec, content []byte, branch string) (bool, error) {
+	remoteContent, statusCode, err := forge.GetContents(ctx, owner, repo, dest, branch)
+	if statusCode == 200 && remoteContent.Content == string(content) {
+		p.logger.Debugf("skipping rename of %s to %s/%s/%s because it was already renamed", oldPath, owner, repo, dest)
+		return false, nil
+	}
+	if statusCode != 200 && statusCode != 404 {
+		return false, fmt.Errorf("failed to fetch contents of file %s/%s/%s: %s", owner, repo, dest, err)
+	}
+
+	return true, p.applyRenameViaGit(ctx, forge, owner, repo, oldPath, dest, content, file.LFS, branch)
+}
+
+// applyRenameViaGit performs the clone/commit/push itself, so ctx only
+// bounds it cooperatively, the same way updateFileViaGit does: it's checked
+// before the clone starts, but go-git's calls below don't accept a context
+// to cancel mid-flight.
+func (p *Processor) applyRenameViaGit(ctx context.Context, forge Forge, owner string, repo string, oldPath string, dest string, content []byte, lfs bool, branch string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if p.dryRun {
+		p.logger.Infof("would rename %s to %s in %s/%s", oldPath, dest, owner, repo)
+		return nil
+	}
+
+	auth, err := resolveGitAuth()
 	if err != nil {
 		return err
 	}
 
-	for _, repo := range resolved.Repos {
-		if len(p.onlyRepo) == 0 || (len(p.onlyRepo) > 0 && repo == p.onlyRepo) {
-			err := f(resolved.Owner, repo)
+	gitRepo, dir, err := p.cloneRepo(forge, owner, repo, auth, branch)
+	p.logger.Debugf("cloned %s/%s to %s: %s", owner, repo, dir, err)
+	if err != nil {
+		return err
+	}
+
+	if lfs {
+		if err := enableLFS(dir, dest); err != nil {
+			return err
+		}
+	}
+
+	oldExists := false
+	if _, err := os.Stat(path.Join(dir, oldPath)); err == nil {
+		oldExists = true
+	}
+
+	fullpath := path.Join(dir, dest)
+	if err := os.WriteFile(fullpath, content, 0777); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("FileMaintainer: Rename %s to %s", oldPath, dest)
+	if lfs {
+		if oldExists {
+			if err := runGitCLI(dir, "rm", "--", oldPath); err != nil {
+				return fmt.Errorf("failed to remove %s while renaming to %s: %s", oldPath, dest, err)
+			}
+		}
+		if err := commitViaGitCLI(dir, []string{dest, ".gitattributes"}, msg); err != nil {
+			return err
+		}
+	} else {
+		wt, err := gitRepo.Worktree()
+		if err != nil {
+			return err
+		}
+
+		if oldExists {
+			if _, err := wt.Remove(oldPath); err != nil {
+				return fmt.Errorf("failed to remove %s while renaming to %s: %s", oldPath, dest, err)
+			}
+		}
+
+		if _, err := wt.Add(dest); err != nil {
+			return err
+		}
+
+		if _, err := wt.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "FileMaintainer",
+				Email: "filemaintainer@users.noreply.github.com",
+				When:  time.Now(),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	p.logger.Debugf("pushing %s", dir)
+	err = p.pushRepo(gitRepo, dir, auth, lfs)
+	p.logger.Debugf("pushed %s: %s", dir, err)
+	return err
+}
+
+// applyAbsent makes sure dest does not exist, in whichever way
+// file.ModeName() calls for.
+func (p *Processor) applyAbsent(ctx context.Context, forge Forge, owner string, repo string, dest string, file FileSpec) error {
+	switch file.ModeName() {
+	case "direct":
+		_, err := p.applyDeleteToBranch(ctx, forge, owner, repo, dest, file, "")
+		return err
+	case "branch":
+		branch := branchNameForFile(file, dest)
+		if !p.dryRun {
+			base, err := forge.DefaultBranch(ctx, owner, repo)
 			if err != nil {
 				return err
 			}
+			if err := forge.EnsureBranch(ctx, owner, repo, branch, base); err != nil {
+				return err
+			}
+		}
+		_, err := p.applyDeleteToBranch(ctx, forge, owner, repo, dest, file, branch)
+		return err
+	case "pull_request":
+		return p.applyDeleteAsPullRequest(ctx, forge, owner, repo, dest, file)
+	default:
+		return fmt.Errorf("file %s has an unrecognized mode %q", dest, file.Mode)
+	}
+}
+
+// applyDeleteToBranch removes dest from branch (the default branch when
+// branch is empty) if it's there, returning whether a commit was actually
+// made.
+func (p *Processor) applyDeleteToBranch(ctx context.Context, forge Forge, owner string, repo string, dest string, file FileSpec, branch string) (bool, error) {
+	remoteContent, statusCode, err := forge.GetContents(ctx, owner, repo, dest, branch)
+	if statusCode == 404 {
+		p.logger.Debugf("skipping delete of %s/%s/%s because it does not exist", owner, repo, dest)
+		return false, nil
+	}
+	if statusCode != 200 {
+		return false, fmt.Errorf("failed to fetch contents of file %s/%s/%s: %s", owner, repo, dest, err)
+	}
+
+	return true, p.deleteFile(ctx, forge, owner, repo, dest, remoteContent.SHA, branch)
+}
+
+// applyDeleteAsPullRequest ensures a dedicated branch has dest removed, then
+// opens (or reuses) a pull request for it.
+func (p *Processor) applyDeleteAsPullRequest(ctx context.Context, forge Forge, owner string, repo string, dest string, file FileSpec) error {
+	branch := branchNameForFile(file, dest)
+	if p.dryRun {
+		p.logger.Infof("would ensure branch %s and open a pull request to delete %s/%s/%s", branch, owner, repo, dest)
+		return nil
+	}
+
+	base, err := forge.DefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := forge.EnsureBranch(ctx, owner, repo, branch, base); err != nil {
+		return err
+	}
+
+	changed, err := p.applyDeleteToBranch(ctx, forge, owner, repo, dest, file, branch)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		p.logger.Debugf("skipping pull request for %s/%s/%s because it's already absent on %s", owner, repo, dest, branch)
+		return nil
+	}
+
+	number, found, err := forge.FindPullRequest(ctx, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+	if !found {
+		number, err = forge.CreatePullRequest(ctx, owner, repo, PullRequestOptions{
+			Title:     file.prDeleteTitle(dest),
+			Body:      file.prDeleteBody(dest),
+			Head:      branch,
+			Base:      base,
+			Labels:    file.PRLabels,
+			Reviewers: file.PRReviewers,
+		})
+		if err != nil {
+			return err
+		}
+		p.logger.Infof("opened pull request #%d for %s/%s/%s", number, owner, repo, dest)
+	} else {
+		p.logger.Infof("updated existing pull request #%d for %s/%s/%s", number, owner, repo, dest)
+	}
+
+	if p.autoMerge {
+		if err := forge.EnableAutoMerge(ctx, owner, repo, number); err != nil {
+			p.logger.Debugf("could not enable auto-merge for %s/%s#%d: %s", owner, repo, number, err)
 		}
 	}
 	return nil
 }
+
+func (p *Processor) deleteFile(ctx context.Context, forge Forge, owner string, repo string, dest string, sha string, branch string) error {
+	if p.dryRun {
+		p.logger.Infof("would delete file %s/%s/%s", owner, repo, dest)
+		return nil
+	}
+
+	msg := fmt.Sprintf("FileMaintainer: Delete %s", dest)
+	statusCode, err := forge.DeleteFile(ctx, owner, repo, dest, msg, sha, branch)
+	if statusCode == 409 {
+		p.logger.Debugf("could not delete file via API due to conflict (will try git-based delete): %s", err)
+		return p.deleteFileViaGit(ctx, forge, owner, repo, dest, branch)
+	}
+	if err != nil {
+		return err
+	}
+	p.logger.Infof("deleted %s/%s/%s", owner, repo, dest)
+	return nil
+}
+
+// deleteFileViaGit performs the clone/commit/push itself, so ctx only bounds
+// it cooperatively: it's checked before the clone starts, but go-git's clone,
+// commit, and push calls below don't accept a context to cancel mid-flight.
+func (p *Processor) deleteFileViaGit(ctx context.Context, forge Forge, owner string, repo string, dest string, branch string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if p.dryRun {
+		p.logger.Infof("would delete file %s/%s/%s", owner, repo, dest)
+		return nil
+	}
+
+	auth, err := resolveGitAuth()
+	if err != nil {
+		return err
+	}
+
+	gitRepo, dir, err := p.cloneRepo(forge, owner, repo, auth, branch)
+	p.logger.Debugf("cloned %s/%s to %s: %s", owner, repo, dir, err)
+	if err != nil {
+		return err
+	}
+
+	if err := p.removeFileFromRepo(gitRepo, dest); err != nil {
+		return err
+	}
+
+	p.logger.Debugf("pushing %s", dir)
+	err = p.pushRepo(gitRepo, dir, auth, false)
+	p.logger.Debugf("pushed %s: %s", dir, err)
+	return err
+}
+
+func (p *Processor) removeFileFromRepo(gitRepo *git.Repository, dest string) error {
+	wt, err := gitRepo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if _, err := wt.Remove(dest); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("FileMaintainer: Delete %s", dest)
+	_, err = wt.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "FileMaintainer",
+			Email: "filemaintainer@users.noreply.github.com",
+			When:  time.Now(),
+		},
+	})
+	return err
+}
+
+// applyToAllRepos runs f over every repo resolved for remote on a worker
+// pool bounded by p.concurrency. RemoteResolver.ResolveRemote above still
+// runs to completion first (and serializes per-remote via its own locking),
+// so only the per-repo callbacks are parallelized. By default all repos are
+// attempted and their errors aggregated into one multierror; p.failFast
+// switches to aborting as soon as the first repo fails. Each call to f gets
+// its own child context, bounded by p.perRepoTimeout (when set) and
+// cancelled alongside ctx.
+func (p *Processor) applyToAllRepos(ctx context.Context, forge Forge, remote RemoteSpec, remoteName string, f func(ctx context.Context, owner string, repo string, resolved *ResolvedRemote) error) error {
+	resolved, err := p.resolver.ResolveRemote(ctx, forge, remote, remoteName)
+	p.logger.Debugf("resolved %s as %+v %s", remote, resolved, err)
+	if err != nil {
+		return err
+	}
+
+	repos := resolved.Repos
+	if len(p.onlyRepo) > 0 {
+		repos = filterRepos(repos, p.onlyRepo)
+	}
+	total := len(repos)
+
+	var (
+		done    int32
+		failed  int32
+		aborted int32
+		mu      sync.Mutex
+		errs    error
+	)
+
+	g := new(errgroup.Group)
+	g.SetLimit(p.concurrency)
+
+	for _, repo := range repos {
+		repo := repo
+		g.Go(func() error {
+			if p.failFast && atomic.LoadInt32(&aborted) == 1 {
+				return nil
+			}
+
+			repoCtx := ctx
+			if p.perRepoTimeout > 0 {
+				var cancel context.CancelFunc
+				repoCtx, cancel = context.WithTimeout(ctx, p.perRepoTimeout)
+				defer cancel()
+			}
+
+			repoErr := f(repoCtx, resolved.Owner, repo, resolved)
+
+			doneCount := atomic.AddInt32(&done, 1)
+			if repoErr != nil {
+				atomic.AddInt32(&failed, 1)
+				mu.Lock()
+				errs = multierror.Append(errs, fmt.Errorf("%s/%s: %w", resolved.Owner, repo, repoErr))
+				mu.Unlock()
+				if p.failFast {
+					atomic.StoreInt32(&aborted, 1)
+				}
+			}
+			p.logger.Infof("%s: %d/%d repos done, %d failed", remoteName, doneCount, total, atomic.LoadInt32(&failed))
+
+			if p.failFast {
+				return repoErr
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return errs
+}
+
+func filterRepos(repos []string, only string) []string {
+	filtered := make([]string, 0, 1)
+	for _, repo := range repos {
+		if repo == only {
+			filtered = append(filtered, repo)
+		}
+	}
+	return filtered
+}
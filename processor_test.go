@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBranchNameForFile(t *testing.T) {
+	if got := branchNameForFile(FileSpec{Branch: "custom"}, ".github/workflows/ci.yml"); got != "custom" {
+		t.Errorf("branchNameForFile() = %q, want %q", got, "custom")
+	}
+
+	first := branchNameForFile(FileSpec{}, ".github/workflows/ci.yml")
+	second := branchNameForFile(FileSpec{}, ".github/workflows/ci.yml")
+	if first != second {
+		t.Errorf("branchNameForFile() should be deterministic for the same dest, got %q and %q", first, second)
+	}
+
+	other := branchNameForFile(FileSpec{}, "README.md")
+	if first == other {
+		t.Errorf("branchNameForFile() should differ for different dests, got %q for both", first)
+	}
+}
+
+func TestFilterRepos(t *testing.T) {
+	repos := []string{"a", "b", "c"}
+
+	if got := filterRepos(repos, "b"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("filterRepos(%v, %q) = %v, want [b]", repos, "b", got)
+	}
+
+	if got := filterRepos(repos, "missing"); len(got) != 0 {
+		t.Errorf("filterRepos(%v, %q) = %v, want empty", repos, "missing", got)
+	}
+}
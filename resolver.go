@@ -6,29 +6,50 @@ import (
 	"sync"
 
 	"github.com/bmatcuk/doublestar/v4"
-	"github.com/google/go-github/v52/github"
 	"go.uber.org/zap"
 )
 
 type ResolvedRemote struct {
 	Owner string
 	Repos []string
+
+	metadataLock sync.Mutex
+	metadata     map[string]RepoMetadata
+}
+
+// Metadata fetches and caches repo's RepoMetadata, so that templating many
+// files against the same repo only hits the forge's API once.
+func (rr *ResolvedRemote) Metadata(ctx context.Context, forge Forge, repo string) (RepoMetadata, error) {
+	rr.metadataLock.Lock()
+	defer rr.metadataLock.Unlock()
+
+	if rr.metadata == nil {
+		rr.metadata = make(map[string]RepoMetadata)
+	}
+	if cached, ok := rr.metadata[repo]; ok {
+		return cached, nil
+	}
+
+	metadata, err := forge.RepoMetadata(ctx, rr.Owner, repo)
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+	rr.metadata[repo] = metadata
+	return metadata, nil
 }
 
 type RemoteResolver struct {
 	resolved    map[string]*ResolvedRemote
 	mainLock    *sync.Mutex
 	remoteLocks map[string]*sync.Mutex
-	gh          *github.Client
 	logger      *zap.SugaredLogger
 }
 
-func NewRemoteResolver(gh *github.Client, logger *zap.SugaredLogger) *RemoteResolver {
+func NewRemoteResolver(logger *zap.SugaredLogger) *RemoteResolver {
 	return &RemoteResolver{
 		resolved:    make(map[string]*ResolvedRemote),
 		mainLock:    &sync.Mutex{},
 		remoteLocks: make(map[string]*sync.Mutex),
-		gh:          gh,
 		logger:      logger,
 	}
 }
@@ -41,7 +62,7 @@ func (resolver *RemoteResolver) ensureLockPresent(remoteName string) {
 	}
 }
 
-func (resolver *RemoteResolver) ResolveRemote(remote RemoteSpec, remoteName string) (*ResolvedRemote, error) {
+func (resolver *RemoteResolver) ResolveRemote(ctx context.Context, forge Forge, remote RemoteSpec, remoteName string) (*ResolvedRemote, error) {
 	resolver.logger.Debugf("resolving remote %+v", remote)
 
 	resolver.ensureLockPresent(remoteName)
@@ -61,30 +82,30 @@ func (resolver *RemoteResolver) ResolveRemote(remote RemoteSpec, remoteName stri
 		resolved = &ResolvedRemote{Owner: remote.Owner(), Repos: remote.Repos}
 	} else if len(remote.RepoGlob) > 0 {
 		// Find the repos matching the glob
-		repos, err := listAllRepos(resolver.gh, remote)
+		repos, err := listAllRepos(ctx, forge, remote)
 		if err != nil {
 			return nil, err
 		}
 
 		repoNames := []string{}
 		for _, repo := range repos {
-			ok, _ := doublestar.Match(remote.RepoGlob, *repo.Name)
+			ok, _ := doublestar.Match(remote.RepoGlob, repo.Name)
 			if ok {
-				repoNames = append(repoNames, *repo.Name)
+				repoNames = append(repoNames, repo.Name)
 			}
 		}
 
 		resolved = &ResolvedRemote{Owner: remote.Owner(), Repos: repoNames}
 	} else {
 		// Find all repos in the org
-		repos, err := listAllRepos(resolver.gh, remote)
+		repos, err := listAllRepos(ctx, forge, remote)
 		if err != nil {
 			return nil, err
 		}
 
 		repoNames := []string{}
 		for _, repo := range repos {
-			repoNames = append(repoNames, *repo.Name)
+			repoNames = append(repoNames, repo.Name)
 		}
 
 		resolved = &ResolvedRemote{Owner: remote.Owner(), Repos: repoNames}
@@ -94,57 +115,39 @@ func (resolver *RemoteResolver) ResolveRemote(remote RemoteSpec, remoteName stri
 	return resolved, nil
 }
 
-func listAllRepos(gh *github.Client, remote RemoteSpec) ([]*github.Repository, error) {
-	repos := []*github.Repository{}
+func listAllRepos(ctx context.Context, forge Forge, remote RemoteSpec) ([]RepoInfo, error) {
+	repos := []RepoInfo{}
 	page := 1
 	for {
-		var (
-			respRepos []*github.Repository
-			resp      *github.Response
-			err       error
-		)
-		if len(remote.User) > 0 {
-			respRepos, resp, err = gh.Repositories.List(
-				context.Background(),
-				remote.User,
-				&github.RepositoryListOptions{
-					Affiliation: "owner",
-					ListOptions: github.ListOptions{Page: page, PerPage: 100},
-				},
-			)
-		} else {
-			respRepos, resp, err = gh.Repositories.ListByOrg(
-				context.Background(),
-				remote.Org,
-				&github.RepositoryListByOrgOptions{
-					ListOptions: github.ListOptions{Page: page, PerPage: 100},
-				},
-			)
-		}
-		if resp.StatusCode != 200 {
-			return []*github.Repository{}, fmt.Errorf("failed to list repos for %v: %s", remote, err)
+		result, err := forge.ListRepos(ctx, remote.Owner(), ForgeListReposOptions{
+			IsUser:  len(remote.User) > 0,
+			Page:    page,
+			PerPage: 100,
+		})
+		if err != nil {
+			return []RepoInfo{}, fmt.Errorf("failed to list repos for %v: %s", remote, err)
 		}
 
 		// Don't try to update archived repos
 		// Respect remote.ExcludeRepos
-		for _, repo := range respRepos {
+		for _, repo := range result.Repos {
 			excluded := false
 			for _, excludedRepo := range remote.ExcludeRepos {
-				if *repo.Name == excludedRepo {
+				if repo.Name == excludedRepo {
 					excluded = true
 					break
 				}
 			}
 
-			if !*repo.Archived && !excluded {
+			if !repo.Archived && !excluded {
 				repos = append(repos, repo)
 			}
 		}
 
-		if resp.NextPage == 0 {
+		if result.NextPage == 0 {
 			break
 		}
-		page = resp.NextPage
+		page = result.NextPage
 	}
 	return repos, nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// TemplateContext is the data available to a FileSpec's Path and Dest
+// templates when Template is true.
+type TemplateContext struct {
+	Owner         string
+	Repo          string
+	DefaultBranch string
+	Topics        []string
+	Vars          map[string]string
+	Now           time.Time
+}
+
+// templateContext builds the TemplateContext for repo, fetching its
+// metadata through resolved's cache so repeated calls for the same repo
+// don't re-hit the forge's API.
+func (p *Processor) templateContext(ctx context.Context, forge Forge, resolved *ResolvedRemote, repo string, vars map[string]string) (TemplateContext, error) {
+	metadata, err := resolved.Metadata(ctx, forge, repo)
+	if err != nil {
+		return TemplateContext{}, fmt.Errorf("failed to fetch metadata for %s/%s: %s", resolved.Owner, repo, err)
+	}
+
+	return TemplateContext{
+		Owner:         resolved.Owner,
+		Repo:          repo,
+		DefaultBranch: metadata.DefaultBranch,
+		Topics:        metadata.Topics,
+		Vars:          vars,
+		Now:           time.Now(),
+	}, nil
+}
+
+func renderTemplate(name string, text string, data TemplateContext) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %s", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %s", name, err)
+	}
+	return buf.String(), nil
+}
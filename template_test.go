@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestRenderTemplate(t *testing.T) {
+	ctx := TemplateContext{
+		Owner:         "octocat",
+		Repo:          "hello-world",
+		DefaultBranch: "main",
+		Vars:          map[string]string{"lang": "go"},
+	}
+
+	got, err := renderTemplate("dest", ".github/workflows/{{.Vars.lang}}-{{.Repo}}.yml", ctx)
+	if err != nil {
+		t.Fatalf("renderTemplate returned an error: %s", err)
+	}
+	if want := ".github/workflows/go-hello-world.yml"; got != want {
+		t.Errorf("renderTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateInvalid(t *testing.T) {
+	if _, err := renderTemplate("dest", "{{.Nope", TemplateContext{}); err == nil {
+		t.Error("renderTemplate with malformed template should have returned an error")
+	}
+}